@@ -0,0 +1,146 @@
+// Package config loads the tool's run-time settings from a YAML file: the
+// model fallback ladder, timeout and retry count, coverage threshold,
+// codebase include/exclude globs, toolchain paths, and the prompt template
+// to use. It's a small Viper-style loader rather than Viper itself — just
+// sane defaults overlaid with whatever the file sets, so a missing or
+// partial config.yaml degrades to the tool's previous hardcoded behavior.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelConfig is one rung of the model fallback ladder: a model name plus
+// the Ollama generation options to call it with.
+type ModelConfig struct {
+	Name       string `yaml:"name"`
+	NumCtx     int    `yaml:"num_ctx"`
+	NumPredict int    `yaml:"num_predict"`
+}
+
+// Config is the tool's full run-time configuration.
+type Config struct {
+	// Models is the ordered fallback ladder generateTests works through;
+	// the first entry is tried first.
+	Models []ModelConfig
+
+	// Timeout bounds a single model call.
+	Timeout time.Duration
+
+	// RetryCount is how many times a single model is retried before the
+	// next rung of the ladder is tried.
+	RetryCount int
+
+	// CoverageThreshold is the minimum line coverage a generated test file
+	// must reach before it's written to ./tests.
+	CoverageThreshold float64
+
+	// Include and Exclude are path/filepath.Match glob patterns applied to
+	// a discovered unit's path; a unit must match Include (if non-empty)
+	// and must not match Exclude. Patterns follow filepath.Match syntax, so
+	// "*" matches within one path segment rather than recursing through
+	// directories.
+	Include []string
+	Exclude []string
+
+	// GTestPrefix, CXX, and Gcov are the cppgtest backend's toolchain paths.
+	GTestPrefix string
+	CXX         string
+	Gcov        string
+
+	// PromptTemplate is a path to a text/template file the cppgtest backend
+	// renders with {{.ClassName}}, {{.Methods}}, and {{.Code}}. Empty means
+	// use the backend's built-in default template.
+	PromptTemplate string
+}
+
+// Default returns the settings the tool used before config.yaml existed, so
+// a missing or partial file falls back to the old hardcoded behavior.
+func Default() *Config {
+	return &Config{
+		Models: []ModelConfig{
+			{Name: "qwen2.5-coder:7b", NumCtx: 131072, NumPredict: 1024},
+		},
+		Timeout:           5 * time.Minute,
+		RetryCount:        3,
+		CoverageThreshold: 80.0,
+		GTestPrefix:       "/opt/homebrew/opt/googletest",
+		CXX:               "g++",
+		Gcov:              "gcov",
+	}
+}
+
+// yamlConfig mirrors Config for decoding; Timeout is a duration string here
+// (e.g. "5m") since yaml.v3 can't unmarshal a string straight into
+// time.Duration.
+type yamlConfig struct {
+	Models            []ModelConfig `yaml:"models"`
+	Timeout           string        `yaml:"timeout"`
+	RetryCount        int           `yaml:"retry_count"`
+	CoverageThreshold float64       `yaml:"coverage_threshold"`
+	Include           []string      `yaml:"include"`
+	Exclude           []string      `yaml:"exclude"`
+	GTestPrefix       string        `yaml:"gtest_prefix"`
+	CXX               string        `yaml:"cxx"`
+	Gcov              string        `yaml:"gcov"`
+	PromptTemplate    string        `yaml:"prompt_template"`
+}
+
+// Load reads path and overlays it onto Default(). A missing file is not an
+// error: every setting just keeps its default.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: read %s: %v", path, err)
+	}
+
+	raw := yamlConfig{
+		Models:            cfg.Models,
+		Timeout:           cfg.Timeout.String(),
+		RetryCount:        cfg.RetryCount,
+		CoverageThreshold: cfg.CoverageThreshold,
+		Include:           cfg.Include,
+		Exclude:           cfg.Exclude,
+		GTestPrefix:       cfg.GTestPrefix,
+		CXX:               cfg.CXX,
+		Gcov:              cfg.Gcov,
+		PromptTemplate:    cfg.PromptTemplate,
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("config: parse %s: %v", path, err)
+	}
+	if len(raw.Models) == 0 {
+		return nil, fmt.Errorf("config: %s must list at least one model", path)
+	}
+	timeout, err := time.ParseDuration(raw.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: invalid timeout %q: %v", path, raw.Timeout, err)
+	}
+	if raw.PromptTemplate != "" && !filepath.IsAbs(raw.PromptTemplate) {
+		raw.PromptTemplate = filepath.Join(filepath.Dir(path), raw.PromptTemplate)
+	}
+
+	*cfg = Config{
+		Models:            raw.Models,
+		Timeout:           timeout,
+		RetryCount:        raw.RetryCount,
+		CoverageThreshold: raw.CoverageThreshold,
+		Include:           raw.Include,
+		Exclude:           raw.Exclude,
+		GTestPrefix:       raw.GTestPrefix,
+		CXX:               raw.CXX,
+		Gcov:              raw.Gcov,
+		PromptTemplate:    raw.PromptTemplate,
+	}
+	return cfg, nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string // empty means don't write a file at all
+		check   func(t *testing.T, cfg *Config, dir string)
+		wantErr bool
+	}{
+		{
+			name:  "missing file falls back to Default",
+			check: func(t *testing.T, cfg *Config, dir string) { assertEqualConfig(t, cfg, Default()) },
+		},
+		{
+			name: "partial file overlays onto Default, leaving the rest",
+			yaml: `
+coverage_threshold: 90.0
+`,
+			check: func(t *testing.T, cfg *Config, dir string) {
+				want := Default()
+				want.CoverageThreshold = 90.0
+				assertEqualConfig(t, cfg, want)
+			},
+		},
+		{
+			name: "full file overrides every field",
+			yaml: `
+models:
+  - name: llama3:8b
+    num_ctx: 8192
+    num_predict: 512
+timeout: 90s
+retry_count: 5
+coverage_threshold: 70.5
+include: ["*.cpp"]
+exclude: ["*_skip.cpp"]
+gtest_prefix: /usr/local/opt/googletest
+cxx: clang++
+gcov: llvm-cov
+prompt_template: custom.tmpl
+`,
+			check: func(t *testing.T, cfg *Config, dir string) {
+				want := &Config{
+					Models:            []ModelConfig{{Name: "llama3:8b", NumCtx: 8192, NumPredict: 512}},
+					Timeout:           90 * time.Second,
+					RetryCount:        5,
+					CoverageThreshold: 70.5,
+					Include:           []string{"*.cpp"},
+					Exclude:           []string{"*_skip.cpp"},
+					GTestPrefix:       "/usr/local/opt/googletest",
+					CXX:               "clang++",
+					Gcov:              "llvm-cov",
+					PromptTemplate:    filepath.Join(dir, "custom.tmpl"),
+				}
+				assertEqualConfig(t, cfg, want)
+			},
+		},
+		{
+			name: "relative prompt_template resolves against the config file's directory",
+			yaml: `prompt_template: prompts/main.tmpl`,
+			check: func(t *testing.T, cfg *Config, dir string) {
+				want := filepath.Join(dir, "prompts/main.tmpl")
+				if cfg.PromptTemplate != want {
+					t.Errorf("PromptTemplate = %q, want %q", cfg.PromptTemplate, want)
+				}
+			},
+		},
+		{
+			name:    "invalid timeout is an error",
+			yaml:    `timeout: "not a duration"`,
+			wantErr: true,
+		},
+		{
+			name:    "empty models list is an error",
+			yaml:    `models: []`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed yaml is an error",
+			yaml:    "models: [\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			if tt.yaml != "" {
+				writeFile(t, path, tt.yaml)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Load() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			tt.check(t, cfg, dir)
+		})
+	}
+}
+
+func assertEqualConfig(t *testing.T, got, want *Config) {
+	t.Helper()
+	if got.Timeout != want.Timeout ||
+		got.RetryCount != want.RetryCount ||
+		got.CoverageThreshold != want.CoverageThreshold ||
+		got.GTestPrefix != want.GTestPrefix ||
+		got.CXX != want.CXX ||
+		got.Gcov != want.Gcov ||
+		got.PromptTemplate != want.PromptTemplate ||
+		len(got.Models) != len(want.Models) ||
+		len(got.Include) != len(want.Include) ||
+		len(got.Exclude) != len(want.Exclude) {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+	for i := range got.Models {
+		if got.Models[i] != want.Models[i] {
+			t.Errorf("Models[%d] = %+v, want %+v", i, got.Models[i], want.Models[i])
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test fixture %s: %v", path, err)
+	}
+}
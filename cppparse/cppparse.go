@@ -0,0 +1,140 @@
+// Package cppparse extracts public class interfaces from C++ headers so the
+// generator can target whatever class a header actually declares instead of
+// a hardcoded Calculator. It works by regex-scanning the header text rather
+// than shelling out to a compiler; that keeps the tool dependency-free, at
+// the cost of not understanding macros, templates, or multi-class headers.
+package cppparse
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Param is one parameter of a method signature, e.g. "int a" -> {Type: "int", Name: "a"}.
+type Param struct {
+	Type string
+	Name string
+}
+
+// Method is a single public member function discovered on a Class.
+type Method struct {
+	Name       string
+	ReturnType string
+	Params     []Param
+}
+
+// Class is a C++ class declaration together with its public methods, in the
+// order they appear in the header.
+type Class struct {
+	Name    string
+	Methods []Method
+}
+
+var (
+	classRe       = regexp.MustCompile(`class\s+(\w+)\s*(?:final\s*)?(?::[^{]+)?{`)
+	accessRe      = regexp.MustCompile(`^(public|private|protected)\s*:`)
+	methodRe      = regexp.MustCompile(`^([\w:<>,\s\*&]+?)\s+(\w+)\s*\(([^)]*)\)\s*(?:const)?\s*(?:override)?\s*[{;]`)
+	constructorRe = regexp.MustCompile(`^(\w+)\s*\(([^)]*)\)`)
+)
+
+// ParseHeader extracts the first class declared in path along with its
+// public methods. It returns an error if the header can't be read, no
+// class declaration is found, or the class has no public methods.
+func ParseHeader(path string) (*Class, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cppparse: read %s: %v", path, err)
+	}
+	return Parse(string(content))
+}
+
+// Parse extracts the first class declared in src along with its public
+// methods.
+func Parse(src string) (*Class, error) {
+	loc := classRe.FindStringSubmatchIndex(src)
+	if loc == nil {
+		return nil, fmt.Errorf("cppparse: no class declaration found")
+	}
+	class := &Class{Name: src[loc[2]:loc[3]]}
+
+	body, err := classBody(src[loc[1]:])
+	if err != nil {
+		return nil, err
+	}
+
+	access := "private" // C++ classes default to private access
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if m := accessRe.FindStringSubmatch(line); m != nil {
+			access = m[1]
+			continue
+		}
+		if access != "public" {
+			continue
+		}
+		if strings.HasPrefix(line, "~") || constructorRe.MatchString(line) && strings.HasPrefix(line, class.Name) {
+			continue // skip constructors/destructors, they aren't unit-testable the same way
+		}
+		m := methodRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		class.Methods = append(class.Methods, Method{
+			ReturnType: strings.TrimSpace(m[1]),
+			Name:       m[2],
+			Params:     parseParams(m[3]),
+		})
+	}
+
+	if len(class.Methods) == 0 {
+		return nil, fmt.Errorf("cppparse: class %s has no public methods", class.Name)
+	}
+	return class, nil
+}
+
+// classBody returns the text between the opening brace of a class (already
+// consumed by the caller) and its matching closing brace.
+func classBody(afterOpenBrace string) (string, error) {
+	depth := 1
+	for i, c := range afterOpenBrace {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return afterOpenBrace[:i], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("cppparse: unterminated class body")
+}
+
+func parseParams(raw string) []Param {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "void" {
+		return nil
+	}
+	var params []Param
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Fields(part)
+		if len(fields) == 1 {
+			params = append(params, Param{Type: fields[0]})
+			continue
+		}
+		params = append(params, Param{
+			Type: strings.Join(fields[:len(fields)-1], " "),
+			Name: fields[len(fields)-1],
+		})
+	}
+	return params
+}
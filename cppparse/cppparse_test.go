@@ -0,0 +1,135 @@
+package cppparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    *Class
+		wantErr bool
+	}{
+		{
+			name: "simple public methods",
+			src: `
+class Calculator {
+public:
+    int add(int a, int b);
+    int subtract(int a, int b);
+};`,
+			want: &Class{
+				Name: "Calculator",
+				Methods: []Method{
+					{ReturnType: "int", Name: "add", Params: []Param{{Type: "int", Name: "a"}, {Type: "int", Name: "b"}}},
+					{ReturnType: "int", Name: "subtract", Params: []Param{{Type: "int", Name: "a"}, {Type: "int", Name: "b"}}},
+				},
+			},
+		},
+		{
+			name: "access specifier switching keeps only public methods",
+			src: `
+class Widget {
+private:
+    int hidden(int x);
+public:
+    int visible(int x);
+protected:
+    int alsoHidden(int x);
+public:
+    int visibleAgain(int x);
+};`,
+			want: &Class{
+				Name: "Widget",
+				Methods: []Method{
+					{ReturnType: "int", Name: "visible", Params: []Param{{Type: "int", Name: "x"}}},
+					{ReturnType: "int", Name: "visibleAgain", Params: []Param{{Type: "int", Name: "x"}}},
+				},
+			},
+		},
+		{
+			name: "defaults to private before first access specifier",
+			src: `
+class Hidden {
+    int notVisible(int x);
+public:
+    int visible(int x);
+};`,
+			want: &Class{
+				Name: "Hidden",
+				Methods: []Method{
+					{ReturnType: "int", Name: "visible", Params: []Param{{Type: "int", Name: "x"}}},
+				},
+			},
+		},
+		{
+			name: "constructor and destructor are skipped",
+			src: `
+class Thing {
+public:
+    Thing(int x);
+    ~Thing();
+    int value();
+};`,
+			want: &Class{
+				Name: "Thing",
+				Methods: []Method{
+					{ReturnType: "int", Name: "value"},
+				},
+			},
+		},
+		{
+			name: "const qualifier doesn't break the signature match, only the first class is parsed",
+			src: `
+class Base {
+public:
+    virtual int area() const;
+};
+class Derived : public Base {
+public:
+    int area() const override;
+};`,
+			// classRe finds the first class declared, so only Base is parsed.
+			want: &Class{
+				Name: "Base",
+				Methods: []Method{
+					{ReturnType: "virtual int", Name: "area"},
+				},
+			},
+		},
+		{
+			name:    "no class declaration",
+			src:     `int freeFunction(int x);`,
+			wantErr: true,
+		},
+		{
+			name: "class with no public methods",
+			src: `
+class Empty {
+private:
+    int hidden(int x);
+};`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+// Package cppgtest is the generator.Generator backend for C++ classes
+// tested with Google Test and gcov, the tool's original (and still default)
+// behavior.
+package cppgtest
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Achanandhi-M/unit-test-generator/config"
+	"github.com/Achanandhi-M/unit-test-generator/cppparse"
+	"github.com/Achanandhi-M/unit-test-generator/generator"
+)
+
+func init() {
+	generator.Register("cpp", func() generator.Generator { return New() })
+}
+
+// defaultGTestPrefix is where Homebrew installs Google Test on the
+// maintainer's machine; Configure overrides it from config.Config.GTestPrefix.
+const defaultGTestPrefix = "/opt/homebrew/opt/googletest"
+
+// defaultPromptTemplate is used when config.Config.PromptTemplate is empty.
+// It takes the same {{.ClassName}}, {{.Methods}}, {{.Code}} placeholders as
+// a custom template file would.
+const defaultPromptTemplate = `You are an expert C++ programmer tasked with generating unit tests using Google Test for the provided C++ code. Follow these requirements strictly:
+- Use C++17 standard.
+- Use ` + "`TEST`" + ` macros with descriptive names (e.g., ` + "`TEST({{.ClassName}}Test, MethodName_PositiveNumbers)`" + `).
+- Write tests for these methods only: {{.Methods}}.
+- Write exactly two test cases per method: one for positive inputs and one for negative inputs.
+- Avoid edge cases involving INT_MIN or INT_MAX to prevent integer overflow issues.
+- Ensure each ` + "`TEST`" + ` macro has complete braces ` + "`{}`" + ` and valid assertions (` + "`EXPECT_EQ`" + `).
+- Output a complete, syntactically correct .cpp file without Markdown code fences, comments outside test code, or extra text.
+
+**Code to test:**
+{{.Code}}
+`
+
+var failedTestRe = regexp.MustCompile(`\[\s+FAILED\s+\]\s+(\S+)`)
+
+// templateData is what the prompt template is rendered with.
+type templateData struct {
+	ClassName string
+	Methods   string
+	Code      string
+}
+
+// Generator implements generator.Generator for C++/Google Test.
+type Generator struct {
+	gtestPrefix    string
+	cxx            string
+	gcov           string
+	promptTemplate string // path to a custom template file; empty uses defaultPromptTemplate
+}
+
+// New returns a ready-to-use cppgtest Generator with its built-in defaults;
+// Configure overlays a config.Config on top.
+func New() *Generator {
+	return &Generator{
+		gtestPrefix: defaultGTestPrefix,
+		cxx:         "g++",
+		gcov:        "gcov",
+	}
+}
+
+// Configure applies cfg's toolchain paths and prompt template override.
+func (g *Generator) Configure(cfg *config.Config) error {
+	if cfg.GTestPrefix != "" {
+		g.gtestPrefix = cfg.GTestPrefix
+	}
+	if cfg.CXX != "" {
+		g.cxx = cfg.CXX
+	}
+	if cfg.Gcov != "" {
+		g.gcov = cfg.Gcov
+	}
+	g.promptTemplate = cfg.PromptTemplate
+	return nil
+}
+
+// Discover walks dir for .cpp/.h pairs and parses each header's class via
+// cppparse, skipping any file missing its counterpart.
+func (g *Generator) Discover(dir string) ([]generator.Unit, error) {
+	var units []generator.Unit
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".cpp") {
+			return nil
+		}
+		headerPath := strings.TrimSuffix(path, ".cpp") + ".h"
+		if _, err := os.Stat(headerPath); os.IsNotExist(err) {
+			log.Printf("cppgtest: skipping %s: no matching header", path)
+			return nil
+		}
+		class, err := cppparse.ParseHeader(headerPath)
+		if err != nil {
+			log.Printf("cppgtest: skipping %s: %v", path, err)
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		units = append(units, generator.Unit{
+			Name:    class.Name,
+			Path:    path,
+			Dir:     filepath.Dir(path),
+			Header:  headerPath,
+			Content: string(content),
+		})
+		return nil
+	})
+	return units, err
+}
+
+func (g *Generator) class(unit generator.Unit) (*cppparse.Class, error) {
+	return cppparse.ParseHeader(unit.Header)
+}
+
+// renderPrompt executes the configured prompt template (or
+// defaultPromptTemplate if none was set) with data.
+func (g *Generator) renderPrompt(data templateData) (string, error) {
+	text := defaultPromptTemplate
+	if g.promptTemplate != "" {
+		b, err := os.ReadFile(g.promptTemplate)
+		if err != nil {
+			return "", fmt.Errorf("read prompt template %s: %v", g.promptTemplate, err)
+		}
+		text = string(b)
+	}
+	tmpl, err := template.New("prompt").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt template: %v", err)
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("execute prompt template: %v", err)
+	}
+	return rendered.String(), nil
+}
+
+// Prompt builds the Google Test generation prompt for unit, requiring one
+// positive and one negative test case per discovered method. The bulk of the
+// instructions come from the configurable prompt template; the required
+// includes and worked example are appended here since they depend on
+// unit.Header, which isn't one of the template's placeholders.
+func (g *Generator) Prompt(unit generator.Unit) (string, error) {
+	class, err := g.class(unit)
+	if err != nil {
+		return "", err
+	}
+	headerInclude := filepath.Base(unit.Header)
+
+	methodNames := make([]string, len(class.Methods))
+	for i, m := range class.Methods {
+		methodNames[i] = m.Name
+	}
+	exampleMethod := class.Methods[0].Name
+
+	instructions, err := g.renderPrompt(templateData{
+		ClassName: class.Name,
+		Methods:   strings.Join(methodNames, ", "),
+		Code:      unit.Content,
+	})
+	if err != nil {
+		return "", fmt.Errorf("prompt template: %v", err)
+	}
+
+	return strings.Join([]string{
+		instructions,
+		fmt.Sprintf("- Include exactly these headers: `#include <gtest/gtest.h>`, `#include <cmath>`, `#include <stdexcept>`, `#include \"%s\"`.", headerInclude),
+		"- Example format:",
+		"#include <gtest/gtest.h>",
+		"#include <cmath>",
+		"#include <stdexcept>",
+		fmt.Sprintf("#include %q", headerInclude),
+		fmt.Sprintf("TEST(%sTest, %s_PositiveNumbers) {", class.Name, strings.Title(exampleMethod)),
+		fmt.Sprintf("    %s instance;", class.Name),
+		fmt.Sprintf("    EXPECT_EQ(instance.%s(2, 3), 5);", exampleMethod),
+		"}",
+		"",
+		"Generate the unit test code as a valid .cpp file following the example format exactly.",
+	}, "\n"), nil
+}
+
+// Validate checks a model response before it is compiled: a minimum length,
+// the required includes, no truncated TEST macro, exactly two TEST cases per
+// discovered method (see validateTestsPerMethod), and balanced braces.
+func (g *Generator) Validate(unit generator.Unit, output string) error {
+	class, err := g.class(unit)
+	if err != nil {
+		return err
+	}
+	headerInclude := filepath.Base(unit.Header)
+
+	if len(output) < 250 {
+		return fmt.Errorf("output too short (%d bytes)", len(output))
+	}
+	if !strings.Contains(output, "#include <gtest/gtest.h>") {
+		return fmt.Errorf("missing #include <gtest/gtest.h>")
+	}
+	if !strings.Contains(output, "#include <cmath>") {
+		return fmt.Errorf("missing #include <cmath>")
+	}
+	if !strings.Contains(output, "#include <stdexcept>") {
+		return fmt.Errorf("missing #include <stdexcept>")
+	}
+	if !strings.Contains(output, "#include \""+headerInclude+"\"") {
+		return fmt.Errorf("missing #include %q", headerInclude)
+	}
+	if !strings.Contains(output, "TEST") {
+		return fmt.Errorf("missing TEST macro")
+	}
+	if regexp.MustCompile(`TEST\([^)]+\)\s*{[^}]*$`).MatchString(output) {
+		return fmt.Errorf("incomplete TEST macro detected")
+	}
+	if err := validateTestsPerMethod(class, output); err != nil {
+		return err
+	}
+	braceCount := 0
+	for _, c := range output {
+		if c == '{' {
+			braceCount++
+		} else if c == '}' {
+			braceCount--
+		}
+	}
+	if braceCount != 0 {
+		return fmt.Errorf("unbalanced braces (count: %d)", braceCount)
+	}
+	return nil
+}
+
+// testCaseRe matches a TEST(<Class>Test, <Method>_<Scenario>) declaration,
+// capturing the method name so validateTestsPerMethod can tally cases per
+// method instead of just the aggregate count.
+var testCaseRe = regexp.MustCompile(`TEST\(\s*(\w+)Test\s*,\s*(\w+)_\w+\s*\)`)
+
+// validateTestsPerMethod requires exactly two TEST cases per method class
+// declares, counted by the method name in each TEST macro; the scenario
+// suffix is not checked, so two positive cases for a method also pass.
+// Checking only the aggregate
+// TEST count let a model pass by writing 3 cases for one method and 1 for
+// another, or by mentioning a method name inside an unrelated test's body;
+// tallying by the name in the TEST macro itself catches both.
+func validateTestsPerMethod(class *cppparse.Class, output string) error {
+	counts := map[string]int{}
+	for _, m := range testCaseRe.FindAllStringSubmatch(output, -1) {
+		if m[1] != class.Name {
+			continue
+		}
+		counts[strings.ToLower(m[2])]++
+	}
+	var bad []string
+	for _, method := range class.Methods {
+		if got := counts[strings.ToLower(method.Name)]; got != 2 {
+			bad = append(bad, fmt.Sprintf("%s (%d)", method.Name, got))
+		}
+	}
+	if len(bad) > 0 {
+		return fmt.Errorf("expected exactly 2 TEST cases (one positive, one negative) per method, got: %v", bad)
+	}
+	return nil
+}
+
+// Build writes the generated test, the source, and its header into tmpDir
+// and compiles them with gcov instrumentation into tmpDir/run_tests.
+func (g *Generator) Build(tmpDir string, unit generator.Unit, testSrc string) error {
+	baseName := filepath.Base(unit.Path)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "test.cpp"), []byte(testSrc), 0644); err != nil {
+		return fmt.Errorf("failed to write test file: %v", err)
+	}
+	if err := copyFile(unit.Path, filepath.Join(tmpDir, baseName)); err != nil {
+		return fmt.Errorf("failed to copy source file: %v", err)
+	}
+	if err := copyFile(unit.Header, filepath.Join(tmpDir, filepath.Base(unit.Header))); err != nil {
+		return fmt.Errorf("failed to copy header file: %v", err)
+	}
+
+	compileCmd := exec.Command(g.cxx,
+		"-std=c++17",
+		"-I"+g.gtestPrefix+"/include",
+		"-I/usr/local/include",
+		"-I"+tmpDir,
+		"-L"+g.gtestPrefix+"/lib",
+		"-L/usr/local/lib",
+		"-lgtest", "-lgtest_main", "-pthread",
+		"-fprofile-arcs", "-ftest-coverage",
+		"test.cpp", baseName,
+		"-o", "run_tests")
+	compileCmd.Dir = tmpDir
+	compileCmd.Env = append(os.Environ(), "GCOV_PREFIX="+tmpDir, "GCOV_PREFIX_STRIP=0")
+
+	log.Printf("cppgtest: compiling: %s", compileCmd.String())
+	compileOutput, err := compileCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("compilation failed: %v\nOutput: %s", err, string(compileOutput))
+	}
+	return nil
+}
+
+// Run executes the compiled gtest binary and reports whether every case
+// passed.
+func (g *Generator) Run(tmpDir string) (bool, error) {
+	runCmd := exec.Command(filepath.Join(tmpDir, "run_tests"))
+	runCmd.Dir = tmpDir
+	log.Printf("cppgtest: running: %s", runCmd.String())
+	runOutput, err := runCmd.CombinedOutput()
+	if err != nil {
+		var failed []string
+		for _, m := range failedTestRe.FindAllStringSubmatch(string(runOutput), -1) {
+			failed = append(failed, m[1])
+		}
+		if len(failed) > 0 {
+			return false, &generator.TestFailureError{
+				Failed: failed,
+				Err:    fmt.Errorf("tests failed (%s): %v\nOutput: %s", strings.Join(failed, ", "), err, string(runOutput)),
+			}
+		}
+		return false, fmt.Errorf("tests failed: %v\nOutput: %s", err, string(runOutput))
+	}
+	return true, nil
+}
+
+// Coverage runs gcov against unit's source file inside tmpDir and parses
+// the "Lines executed" percentage.
+func (g *Generator) Coverage(tmpDir string, unit generator.Unit) (float64, error) {
+	baseName := filepath.Base(unit.Path)
+	gcovCmd := exec.Command(g.gcov, "-r", baseName)
+	gcovCmd.Dir = tmpDir
+	log.Printf("cppgtest: running gcov: %s", gcovCmd.String())
+	gcovOutput, err := gcovCmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("gcov failed: %v\nOutput: %s", err, string(gcovOutput))
+	}
+
+	re := regexp.MustCompile(`Lines executed:([\d.]+)% of (\d+)`)
+	matches := re.FindStringSubmatch(string(gcovOutput))
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("failed to parse gcov output: %s", string(gcovOutput))
+	}
+	coverage, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse coverage percentage: %v", err)
+	}
+	return coverage, nil
+}
+
+func copyFile(src, dst string) error {
+	input, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, input, 0644)
+}
@@ -0,0 +1,139 @@
+// Package generator defines the Generator interface that every language
+// backend (cppgtest, gotest, pytest, ...) implements, plus a small registry
+// so main can pick a backend by name or by auto-detecting the codebase's
+// file extensions instead of being wired to C++/Google Test directly.
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Achanandhi-M/unit-test-generator/config"
+)
+
+// Unit is one discoverable piece of code to generate tests for: a C++
+// class, a Go package, a Python module, etc. Backends populate only the
+// fields relevant to their language.
+type Unit struct {
+	Name    string // class, package, or module name under test
+	Path    string // primary source file, used for Result.File and logging
+	Dir     string // directory containing the unit
+	Header  string // cpp-only: associated header path; empty otherwise
+	Content string // source text to feed into the prompt
+}
+
+// Generator is the per-language backend contract. Discover finds units in a
+// codebase directory; Prompt builds the model request for one; Validate
+// checks a model response before it's ever compiled; Build and Run compile
+// and execute the generated test against tmpDir; Coverage reports the
+// resulting line coverage percentage.
+type Generator interface {
+	Discover(dir string) ([]Unit, error)
+	Prompt(unit Unit) (string, error)
+	Validate(unit Unit, output string) error
+	Build(tmpDir string, unit Unit, testSrc string) error
+	Run(tmpDir string) (bool, error)
+	Coverage(tmpDir string, unit Unit) (float64, error)
+}
+
+// TestFailureError is the error Run should return when the test binary
+// built and ran but one or more individual cases failed, naming them so a
+// repair prompt can ask the model to revise only those cases instead of
+// rewriting the whole file. A backend that can't attribute failures to
+// names, or that hit a harder failure (the binary itself crashed, a runner
+// error), should return a plain error instead.
+type TestFailureError struct {
+	Failed []string
+	Err    error
+}
+
+func (e *TestFailureError) Error() string { return e.Err.Error() }
+func (e *TestFailureError) Unwrap() error { return e.Err }
+
+// Configurable is implemented by backends that accept settings from
+// config.Config beyond what the Generator interface carries (toolchain
+// paths, prompt templates, ...). main calls Configure on any registered
+// backend that implements it after Get; backends with nothing
+// language-specific to configure, like gotest and pytest, can skip it.
+type Configurable interface {
+	Configure(cfg *config.Config) error
+}
+
+// Factory constructs a fresh Generator instance. Backends register one
+// under their language name in an init() func, the same way database/sql
+// drivers register themselves with the sql package.
+type Factory func() Generator
+
+var registry = map[string]Factory{}
+
+// Register makes a Generator available under name (e.g. "cpp", "go",
+// "python"). It panics on duplicate registration, matching the sql/image
+// driver-registry convention.
+func Register(name string, factory Factory) {
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("generator: Register called twice for %q", name))
+	}
+	registry[name] = factory
+}
+
+// Get returns a fresh Generator for name, or false if nothing is registered
+// under it.
+func Get(name string) (Generator, bool) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// Names lists the registered backend names.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
+
+// extLanguage maps a file extension to the backend name that handles it.
+var extLanguage = map[string]string{
+	".cpp": "cpp",
+	".h":   "cpp",
+	".go":  "go",
+	".py":  "python",
+}
+
+// AutoDetect walks dir and returns the backend name for whichever
+// registered language's extensions appear most often, so main can pick a
+// generator without a -lang flag when the codebase is unambiguous.
+func AutoDetect(dir string) (string, error) {
+	counts := map[string]int{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if lang, ok := extLanguage[strings.ToLower(filepath.Ext(path))]; ok {
+			counts[lang]++
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("generator: auto-detect %s: %v", dir, err)
+	}
+
+	best, bestCount := "", 0
+	for lang, count := range counts {
+		if count > bestCount {
+			best, bestCount = lang, count
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("generator: no recognized source files under %s", dir)
+	}
+	return best, nil
+}
@@ -0,0 +1,221 @@
+// Package gotest is the generator.Generator backend for Go packages,
+// tested with `go test`. Coverage is parsed from `go test -json` events
+// rather than a separate coverage tool, since `go test -cover` already
+// reports it.
+package gotest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Achanandhi-M/unit-test-generator/generator"
+)
+
+func init() {
+	generator.Register("go", func() generator.Generator { return New() })
+}
+
+var packageRe = regexp.MustCompile(`(?m)^package\s+(\w+)`)
+
+// Generator implements generator.Generator for Go packages.
+type Generator struct{}
+
+// New returns a ready-to-use gotest Generator.
+func New() *Generator { return &Generator{} }
+
+// Discover groups non-test .go files by directory, one Unit per package.
+func (g *Generator) Discover(dir string) ([]generator.Unit, error) {
+	byDir := map[string][]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		byDir[filepath.Dir(path)] = append(byDir[filepath.Dir(path)], path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var units []generator.Unit
+	for pkgDir, files := range byDir {
+		var content strings.Builder
+		pkgName := filepath.Base(pkgDir)
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if m := packageRe.FindStringSubmatch(string(data)); m != nil {
+				pkgName = m[1]
+			}
+			content.WriteString(string(data))
+			content.WriteString("\n")
+		}
+		units = append(units, generator.Unit{
+			Name:    pkgName,
+			Path:    files[0],
+			Dir:     pkgDir,
+			Content: content.String(),
+		})
+	}
+	return units, nil
+}
+
+// Prompt asks for a standard-library `testing` test file covering the
+// package's exported functions.
+func (g *Generator) Prompt(unit generator.Unit) (string, error) {
+	return strings.Join([]string{
+		"You are an expert Go programmer tasked with generating unit tests using the standard `testing` package for the provided Go code. Follow these requirements strictly:",
+		fmt.Sprintf("- The file must start with `package %s`.", unit.Name),
+		"- Import exactly `\"testing\"` (plus any stdlib package the code under test needs).",
+		"- Write one `func TestXxx(t *testing.T)` per exported function, covering a typical input and an error/edge case.",
+		"- Use `t.Errorf` or `t.Fatalf` for assertions; do not use a third-party assertion library.",
+		"- Output a complete, syntactically correct _test.go file without Markdown code fences, comments outside test code, or extra text.",
+		"",
+		"**Code to test:**",
+		unit.Content,
+		"",
+		"Generate the test file now.",
+	}, "\n"), nil
+}
+
+// Validate checks the response declares the right package, imports
+// "testing", defines at least one test function, and has balanced braces.
+func (g *Generator) Validate(unit generator.Unit, output string) error {
+	if len(output) < 50 {
+		return fmt.Errorf("output too short (%d bytes)", len(output))
+	}
+	if !strings.Contains(output, "package "+unit.Name) {
+		return fmt.Errorf("missing package %s declaration", unit.Name)
+	}
+	if !strings.Contains(output, `"testing"`) {
+		return fmt.Errorf(`missing import "testing"`)
+	}
+	if !regexp.MustCompile(`func Test\w+\(t \*testing\.T\)`).MatchString(output) {
+		return fmt.Errorf("no func TestXxx(t *testing.T) found")
+	}
+	braceCount := 0
+	for _, c := range output {
+		if c == '{' {
+			braceCount++
+		} else if c == '}' {
+			braceCount--
+		}
+	}
+	if braceCount != 0 {
+		return fmt.Errorf("unbalanced braces (count: %d)", braceCount)
+	}
+	return nil
+}
+
+// Build copies the package's sources and the generated test into tmpDir and
+// gives it its own go.mod, since `go test` refuses to run outside a module.
+// There's no separate compile step beyond that; `go test` builds and runs in
+// one invocation.
+func (g *Generator) Build(tmpDir string, unit generator.Unit, testSrc string) error {
+	entries, err := os.ReadDir(unit.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read package dir %s: %v", unit.Dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(unit.Dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, e.Name()), data, 0644); err != nil {
+			return err
+		}
+	}
+	testFile := filepath.Join(tmpDir, unit.Name+"_generated_test.go")
+	if err := os.WriteFile(testFile, []byte(testSrc), 0644); err != nil {
+		return fmt.Errorf("failed to write test file: %v", err)
+	}
+
+	modCmd := exec.Command("go", "mod", "init", unit.Name)
+	modCmd.Dir = tmpDir
+	if out, err := modCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go mod init failed: %v\nOutput: %s", err, string(out))
+	}
+	return nil
+}
+
+// goTestEvent mirrors the subset of `go test -json` event fields this
+// backend cares about.
+type goTestEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// Run executes `go test -cover -json` in tmpDir, leaving the raw event
+// stream in tmpDir/go_test.json for Coverage to parse, and reports whether
+// any test failed.
+func (g *Generator) Run(tmpDir string) (bool, error) {
+	cmd := exec.Command("go", "test", "-cover", "-json", ".")
+	cmd.Dir = tmpDir
+	log.Printf("gotest: running: %s", cmd.String())
+	output, runErr := cmd.CombinedOutput()
+	if err := os.WriteFile(filepath.Join(tmpDir, "go_test.json"), output, 0644); err != nil {
+		log.Printf("gotest: failed to save go_test.json: %v", err)
+	}
+
+	var failed []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var ev goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue // non-JSON line, e.g. a build failure dumped to stdout
+		}
+		if ev.Action == "fail" && ev.Test != "" {
+			failed = append(failed, ev.Test)
+		}
+	}
+	if runErr != nil || len(failed) > 0 {
+		if len(failed) > 0 {
+			return false, &generator.TestFailureError{
+				Failed: failed,
+				Err:    fmt.Errorf("go test failed (%s): %v\nOutput: %s", strings.Join(failed, ", "), runErr, string(output)),
+			}
+		}
+		return false, fmt.Errorf("go test failed: %v\nOutput: %s", runErr, string(output))
+	}
+	return true, nil
+}
+
+var coverageRe = regexp.MustCompile(`coverage:\s+([\d.]+)% of statements`)
+
+// Coverage reads back tmpDir/go_test.json and extracts the `coverage:` line
+// `go test -cover` always emits as an Output event.
+func (g *Generator) Coverage(tmpDir string, unit generator.Unit) (float64, error) {
+	data, err := os.ReadFile(filepath.Join(tmpDir, "go_test.json"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read go_test.json: %v", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var ev goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if m := coverageRe.FindStringSubmatch(ev.Output); m != nil {
+			return strconv.ParseFloat(m[1], 64)
+		}
+	}
+	return 0, fmt.Errorf("no coverage line found in go test output")
+}
@@ -0,0 +1,144 @@
+// Package pytest is the generator.Generator backend for Python modules,
+// tested with pytest and pytest-cov.
+package pytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Achanandhi-M/unit-test-generator/generator"
+)
+
+func init() {
+	generator.Register("python", func() generator.Generator { return New() })
+}
+
+// Generator implements generator.Generator for Python modules.
+type Generator struct{}
+
+// New returns a ready-to-use pytest Generator.
+func New() *Generator { return &Generator{} }
+
+// Discover treats every non-test .py file as its own Unit, named after the
+// module (the filename without its extension).
+func (g *Generator) Discover(dir string) ([]generator.Unit, error) {
+	var units []generator.Unit
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".py") || strings.HasPrefix(name, "test_") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		units = append(units, generator.Unit{
+			Name:    strings.TrimSuffix(name, ".py"),
+			Path:    path,
+			Dir:     filepath.Dir(path),
+			Content: string(content),
+		})
+		return nil
+	})
+	return units, err
+}
+
+// Prompt asks for a pytest test file importing the module under test.
+func (g *Generator) Prompt(unit generator.Unit) (string, error) {
+	return strings.Join([]string{
+		"You are an expert Python programmer tasked with generating unit tests using pytest for the provided Python module. Follow these requirements strictly:",
+		fmt.Sprintf("- Start with `import pytest` and `from %s import *` (or the specific names under test).", unit.Name),
+		"- Write one or more `def test_xxx():` functions per public function, covering a typical input and an error/edge case.",
+		"- Use plain `assert` statements; do not use a third-party assertion library.",
+		"- Output a complete, syntactically correct test_*.py file without Markdown code fences, comments outside test code, or extra text.",
+		"",
+		"**Code to test:**",
+		unit.Content,
+		"",
+		"Generate the test file now.",
+	}, "\n"), nil
+}
+
+// Validate checks the response imports pytest, mentions the module under
+// test by name, and defines at least one test function.
+func (g *Generator) Validate(unit generator.Unit, output string) error {
+	if len(output) < 50 {
+		return fmt.Errorf("output too short (%d bytes)", len(output))
+	}
+	if !strings.Contains(output, "import pytest") {
+		return fmt.Errorf("missing import pytest")
+	}
+	if !strings.Contains(output, unit.Name) {
+		return fmt.Errorf("missing reference to module %s", unit.Name)
+	}
+	if !regexp.MustCompile(`(?m)^def test_\w+\(`).MatchString(output) {
+		return fmt.Errorf("no def test_xxx() found")
+	}
+	return nil
+}
+
+// Build writes the module under test and the generated test file into
+// tmpDir; pytest builds nothing ahead of time.
+func (g *Generator) Build(tmpDir string, unit generator.Unit, testSrc string) error {
+	if err := copyFile(unit.Path, filepath.Join(tmpDir, unit.Name+".py")); err != nil {
+		return fmt.Errorf("failed to copy module: %v", err)
+	}
+	testFile := filepath.Join(tmpDir, "test_"+unit.Name+".py")
+	if err := os.WriteFile(testFile, []byte(testSrc), 0644); err != nil {
+		return fmt.Errorf("failed to write test file: %v", err)
+	}
+	return nil
+}
+
+// Run executes pytest with coverage reporting in JSON, so Coverage can read
+// the result back without a second invocation.
+func (g *Generator) Run(tmpDir string) (bool, error) {
+	cmd := exec.Command("pytest", "-q",
+		"--cov="+".",
+		"--cov-report=json:coverage.json")
+	cmd.Dir = tmpDir
+	log.Printf("pytest: running: %s", cmd.String())
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return false, fmt.Errorf("pytest failed: %v\nOutput: %s", err, string(output))
+	}
+	return true, nil
+}
+
+// coverageJSON mirrors the subset of pytest-cov's `--cov-report=json`
+// output this backend needs.
+type coverageJSON struct {
+	Totals struct {
+		PercentCovered float64 `json:"percent_covered"`
+	} `json:"totals"`
+}
+
+// Coverage reads tmpDir/coverage.json, written by Run's --cov-report=json.
+func (g *Generator) Coverage(tmpDir string, unit generator.Unit) (float64, error) {
+	data, err := os.ReadFile(filepath.Join(tmpDir, "coverage.json"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read coverage.json: %v", err)
+	}
+	var cov coverageJSON
+	if err := json.Unmarshal(data, &cov); err != nil {
+		return 0, fmt.Errorf("failed to parse coverage.json: %v", err)
+	}
+	return cov.Totals.PercentCovered, nil
+}
+
+func copyFile(src, dst string) error {
+	input, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, input, 0644)
+}
@@ -0,0 +1,116 @@
+// Package ollamaapi is a minimal hand-rolled client for the slice of
+// Ollama's HTTP API this tool needs: listing installed models and streaming
+// a completion. The upstream github.com/ollama/ollama/api package pulls in
+// that project's entire server-side dependency graph for a client surface
+// this small, and as of v0.32.4 requires a Go toolchain newer than this
+// repo targets, so we talk to Ollama's documented REST endpoints directly
+// instead of depending on it.
+package ollamaapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Client talks to one Ollama server over HTTP.
+type Client struct {
+	base *url.URL
+	http *http.Client
+}
+
+// NewClient returns a Client targeting base, issuing requests through
+// httpClient.
+func NewClient(base *url.URL, httpClient *http.Client) *Client {
+	return &Client{base: base, http: httpClient}
+}
+
+// Model is one entry returned by List.
+type Model struct {
+	Name string `json:"name"`
+}
+
+// ListResponse is the body of GET /api/tags.
+type ListResponse struct {
+	Models []Model `json:"models"`
+}
+
+// List returns the models currently available on the server.
+func (c *Client) List(ctx context.Context) (*ListResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base.String()+"/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama: GET /api/tags: status %s", resp.Status)
+	}
+	var out ListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("ollama: decode /api/tags response: %v", err)
+	}
+	return &out, nil
+}
+
+// GenerateRequest is the body of POST /api/generate.
+type GenerateRequest struct {
+	Model   string                 `json:"model"`
+	Prompt  string                 `json:"prompt"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// GenerateResponse is one line of the streamed /api/generate response.
+type GenerateResponse struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+}
+
+// Generate streams a completion for req, calling fn with each chunk as it
+// arrives, and returns once the server reports Done or the stream ends.
+func (c *Client) Generate(ctx context.Context, req *GenerateRequest, fn func(GenerateResponse) error) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base.String()+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama: POST /api/generate: status %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var chunk GenerateResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("ollama: decode /api/generate chunk: %v", err)
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	return scanner.Err()
+}
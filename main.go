@@ -2,308 +2,555 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strconv"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 
-	"github.com/ollama/ollama/api"
+	"github.com/Achanandhi-M/unit-test-generator/config"
+	"github.com/Achanandhi-M/unit-test-generator/generator"
+	_ "github.com/Achanandhi-M/unit-test-generator/generator/cppgtest"
+	_ "github.com/Achanandhi-M/unit-test-generator/generator/gotest"
+	_ "github.com/Achanandhi-M/unit-test-generator/generator/pytest"
+	"github.com/Achanandhi-M/unit-test-generator/internal/ollamaapi"
 )
 
-func readCodebase(dir string) (map[string]string, error) {
-	filesContent := make(map[string]string)
-	log.Printf("Reading codebase directory: %s", dir)
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			log.Printf("Error accessing path %s: %v", path, err)
-			return err
-		}
-		if !info.IsDir() && (strings.HasSuffix(info.Name(), ".cpp") || strings.HasSuffix(info.Name(), ".h")) {
-			log.Printf("Found file: %s", path)
-			content, err := os.ReadFile(path)
-			if err != nil {
-				log.Printf("Error reading file %s: %v", path, err)
-				return err
-			}
-			filesContent[path] = string(content)
-			log.Printf("Successfully read file %s (%d bytes)", path, len(content))
-		}
+// Event is one machine-readable line of the -json event stream, mirroring
+// the shape `go test -json` uses: one JSON object per line on stdout,
+// describing a single step of processing a single file.
+type Event struct {
+	Time     time.Time `json:"Time"`
+	Action   string    `json:"Action"` // generate|validate|compile|run|cover|done
+	File     string    `json:"File"`
+	Model    string    `json:"Model,omitempty"`
+	Attempt  int       `json:"Attempt,omitempty"`
+	Coverage float64   `json:"Coverage,omitempty"`
+	Error    string    `json:"Error,omitempty"`
+}
+
+// eventLogger serializes Event writes to stdout across the worker pool. A
+// nil *eventLogger is a valid no-op, so callers don't need to branch on
+// whether -json was passed.
+type eventLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// newEventLogger returns an eventLogger writing to w, or nil if enabled is
+// false.
+func newEventLogger(w io.Writer, enabled bool) *eventLogger {
+	if !enabled {
 		return nil
-	})
-	if err != nil {
-		log.Printf("Failed to walk codebase directory %s: %v", dir, err)
-	} else {
-		log.Printf("Found %d files in codebase", len(filesContent))
 	}
-	return filesContent, err
+	return &eventLogger{enc: json.NewEncoder(w)}
 }
 
-func generateUnitTests(client *api.Client, model, code string) (string, error) {
-	log.Printf("Generating unit tests with model %s (code length: %d bytes)", model, len(code))
-	resp, err := client.List(context.Background())
-	if err != nil {
-		log.Printf("Failed to list models: %v", err)
-		return "", err
+// Log timestamps ev and writes it as one JSON line. It is safe to call from
+// multiple worker goroutines and safe to call on a nil *eventLogger.
+func (el *eventLogger) Log(ev Event) {
+	if el == nil {
+		return
+	}
+	ev.Time = time.Now()
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	if err := el.enc.Encode(ev); err != nil {
+		log.Printf("eventLogger: failed to encode event: %v", err)
+	}
+}
+
+// shardOf hashes path the same way Go's test/run.go distributes testdir work
+// across CI shards: fnv32(path) % shards.
+func shardOf(path string, shards int) int {
+	h := fnv.New32()
+	h.Write([]byte(path))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// filterUnits keeps only units whose Path matches at least one of include
+// (when include is non-empty) and none of exclude. Patterns follow
+// path/filepath.Match syntax against both the full path and its base name.
+func filterUnits(units []generator.Unit, include, exclude []string) []generator.Unit {
+	if len(include) == 0 && len(exclude) == 0 {
+		return units
 	}
-	availableModels := []string{model}
-	for _, m := range resp.Models {
-		if m.Name != model {
-			availableModels = append(availableModels, m.Name)
+	var filtered []generator.Unit
+	for _, u := range units {
+		if len(include) > 0 && !matchAny(include, u.Path) {
+			continue
 		}
+		if matchAny(exclude, u.Path) {
+			continue
+		}
+		filtered = append(filtered, u)
 	}
-	log.Printf("Available models: %v", availableModels)
+	return filtered
+}
 
-	// Explicitly specify methods to test
-	methods := []string{"add", "subtract"}
-	methodsList := strings.Join(methods, ", ")
+// matchAny reports whether path (or its base name) matches any of patterns.
+func matchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, path); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(p, filepath.Base(path)); ok {
+			return true
+		}
+	}
+	return false
+}
 
-	prompt := strings.Join([]string{
-		"You are an expert C++ programmer tasked with generating unit tests using Google Test for the provided C++ code. Follow these requirements strictly:",
-		"- Use C++17 standard.",
-		"- Include exactly these headers: `#include <gtest/gtest.h>`, `#include <cmath>`, `#include <stdexcept>`, `#include \"example.h\"`.",
-		"- Use `TEST` macros with descriptive names (e.g., `TEST(CalculatorTest, Add_PositiveNumbers)).",
-		fmt.Sprintf("- Write tests for these methods only: %s.", methodsList),
-		"- Write exactly 4 test cases (2 per method): one for positive inputs and one for negative inputs.",
-		"- Avoid edge cases involving INT_MIN or INT_MAX to prevent integer overflow issues.",
-		"- Ensure each `TEST` macro has complete braces `{}` and valid assertions (`EXPECT_EQ`).",
-		"- Output a complete, syntactically correct .cpp file without Markdown code fences, comments outside test code, or extra text.",
-		"- Example format:",
-		"#include <gtest/gtest.h>",
-		"#include <cmath>",
-		"#include <stdexcept>",
-		"#include \"example.h\"",
-		"TEST(CalculatorTest, Add_PositiveNumbers) {",
-		"    Calculator calc;",
-		"    EXPECT_EQ(calc.add(2, 3), 5);",
-		"}",
-		"",
-		"**Code to test:**",
-		code,
-		"",
-		"Generate the unit test code as a valid .cpp file following the example format exactly.",
-	}, "\n")
+// modelClient is the subset of *ollamaapi.Client that the generation and
+// repair pipeline needs, so tests can substitute a fake instead of talking
+// to a real Ollama server.
+type modelClient interface {
+	Generate(ctx context.Context, req *ollamaapi.GenerateRequest, fn func(ollamaapi.GenerateResponse) error) error
+}
 
-	log.Printf("Sending API request with prompt (%d bytes)", len(prompt))
-	req := api.GenerateRequest{
-		Model:  model,
+// callModel sends prompt to mc's model and returns the trimmed response
+// text, bounded by timeout.
+func callModel(client modelClient, mc config.ModelConfig, prompt string, timeout time.Duration) (string, error) {
+	req := ollamaapi.GenerateRequest{
+		Model:  mc.Name,
 		Prompt: prompt,
 		Options: map[string]interface{}{
-			"num_ctx":     131072,
-			"num_predict": 1024,
+			"num_ctx":     mc.NumCtx,
+			"num_predict": mc.NumPredict,
 		},
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var result strings.Builder
+	if err := client.Generate(ctx, &req, func(resp ollamaapi.GenerateResponse) error {
+		result.WriteString(resp.Response)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	output := result.String()
+	output = strings.TrimPrefix(output, "```cpp\n")
+	output = strings.TrimPrefix(output, "```go\n")
+	output = strings.TrimPrefix(output, "```python\n")
+	output = strings.TrimPrefix(output, "```\n")
+	output = strings.TrimSuffix(output, "\n```")
+	return strings.TrimSpace(output), nil
+}
 
-	for _, currentModel := range availableModels {
-		req.Model = currentModel
-		log.Printf("Trying model %s", currentModel)
-		var result strings.Builder
-		for attempt := 1; attempt <= 3; attempt++ {
-			log.Printf("Attempt %d of 3 to generate unit tests with model %s", attempt, currentModel)
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-			defer cancel()
-			result.Reset()
-			err := client.Generate(ctx, &req, func(resp api.GenerateResponse) error {
-				result.WriteString(resp.Response)
-				return nil
-			})
+// generateTests works down cfg.Models in order for tests covering unit,
+// retrying up to cfg.RetryCount times per model, and validates each
+// response with gen.Validate before accepting it. It returns the model that
+// produced the accepted output alongside the output itself, since later
+// repair rounds keep using whichever model first succeeded.
+func generateTests(client modelClient, cfg *config.Config, gen generator.Generator, unit generator.Unit, ev *eventLogger) (string, config.ModelConfig, int, error) {
+	prompt, err := gen.Prompt(unit)
+	if err != nil {
+		return "", config.ModelConfig{}, 0, fmt.Errorf("prompt: %v", err)
+	}
+
+	for _, mc := range cfg.Models {
+		for attempt := 1; attempt <= cfg.RetryCount; attempt++ {
+			log.Printf("Generating tests for %s with %s (attempt %d/%d)", unit.Name, mc.Name, attempt, cfg.RetryCount)
+			ev.Log(Event{Action: "generate", File: unit.Path, Model: mc.Name, Attempt: attempt})
+			output, err := callModel(client, mc, prompt, cfg.Timeout)
 			if err != nil {
-				log.Printf("Attempt %d failed with model %s: %v", attempt, currentModel, err)
+				log.Printf("Attempt %d failed with model %s: %v", attempt, mc.Name, err)
+				ev.Log(Event{Action: "generate", File: unit.Path, Model: mc.Name, Attempt: attempt, Error: err.Error()})
 				time.Sleep(time.Second)
 				continue
 			}
-			output := result.String()
-			output = strings.TrimPrefix(output, "```cpp\n")
-			output = strings.TrimSuffix(output, "\n```")
-			output = strings.TrimSpace(output)
-
-			// Save raw response for debugging
-			if err := os.WriteFile(fmt.Sprintf("raw_response_%s_attempt_%d.txt", currentModel, attempt), []byte(output), 0644); err != nil {
+			if err := saveRawResponse(unit.Path, mc.Name, attempt, output); err != nil {
 				log.Printf("Failed to save raw response: %v", err)
 			}
-
-			// Validate output
-			if len(output) < 250 {
-				log.Printf("Validation failed: Output too short (%d bytes)", len(output))
-				continue
-			}
-			if !strings.Contains(output, "#include <gtest/gtest.h>") {
-				log.Printf("Validation failed: Missing #include <gtest/gtest.h>")
-				continue
-			}
-			if !strings.Contains(output, "#include <cmath>") {
-				log.Printf("Validation failed: Missing #include <cmath>")
-				continue
-			}
-			if !strings.Contains(output, "#include <stdexcept>") {
-				log.Printf("Validation failed: Missing #include <stdexcept>")
-				continue
-			}
-			if !strings.Contains(output, "#include \"example.h\"") {
-				log.Printf("Validation failed: Missing #include \"example.h\"")
-				continue
-			}
-			if !strings.Contains(output, "TEST") {
-				log.Printf("Validation failed: Missing TEST macro")
-				continue
-			}
-			re := regexp.MustCompile(`TEST\([^)]+\)\s*{[^}]*$`)
-			if re.MatchString(output) {
-				log.Printf("Validation failed: Incomplete TEST macro detected")
-				continue
-			}
-			// Check for exactly 4 TEST cases
-
-			testCount := len(regexp.MustCompile(`TEST\(CalculatorTest,`).FindAllString(output, -1))
-			if testCount != 4 {
-				log.Printf("Validation failed: Expected exactly 4 TEST cases, found %d", testCount)
-				continue
-			}
-			missingMethods := []string{}
-			for _, method := range methods {
-				if !strings.Contains(output, method+"(") {
-					missingMethods = append(missingMethods, method)
-				}
-			}
-			if len(missingMethods) > 0 {
-				log.Printf("Validation failed: Missing tests for methods: %v", missingMethods)
-				continue
-			}
-			braceCount := 0
-			for _, c := range output {
-				if c == '{' {
-					braceCount++
-				} else if c == '}' {
-					braceCount--
-				}
-			}
-			if braceCount != 0 {
-				log.Printf("Validation failed: Unbalanced braces (count: %d)", braceCount)
+			if err := gen.Validate(unit, output); err != nil {
+				log.Printf("Validation failed: %v", err)
+				ev.Log(Event{Action: "validate", File: unit.Path, Model: mc.Name, Attempt: attempt, Error: err.Error()})
 				continue
 			}
-
-			log.Printf("Successfully generated unit tests (%d bytes) with model %s", len(output), currentModel)
-			return output, nil
+			ev.Log(Event{Action: "validate", File: unit.Path, Model: mc.Name, Attempt: attempt})
+			return output, mc, attempt, nil
 		}
 	}
-	log.Printf("Failed to generate unit tests after 3 attempts with all models")
-	return "", fmt.Errorf("failed after 3 attempts with all models")
+	return "", config.ModelConfig{}, cfg.RetryCount, fmt.Errorf("failed after %d attempts with all models", cfg.RetryCount)
+}
+
+// repairTask describes, in the repair prompt, what the model should
+// actually do about the previous round's outcome: touch only the named
+// failing cases when the binary ran but some failed; add coverage when it
+// built and every case passed but fell short of the threshold; or rewrite
+// the whole file on a harder failure (a build error, a crashed binary).
+// Getting this branch wrong is actively misleading — e.g. telling the
+// model "the file failed to build" when it built and passed fine but was
+// merely under-covered.
+func repairTask(outcome roundOutcome) string {
+	switch {
+	case len(outcome.FailedTests) > 0:
+		return fmt.Sprintf("Revise only the failing test cases (%s); leave the other TEST blocks exactly as written.", strings.Join(outcome.FailedTests, ", "))
+	case outcome.BuildOK && outcome.RunOK:
+		return "The file built and passed, but coverage is below the threshold; add tests to cover the remaining branches."
+	default:
+		return "The file failed to build or run; return a fully corrected version."
+	}
 }
 
-func runTestsAndCoverage(testFile, sourceFile string) (bool, float64, error) {
-	brewPrefix := "/opt/homebrew/opt/googletest"
-	baseName := filepath.Base(sourceFile)
+// generateRepair feeds the previous round's output and outcome back to mc's
+// model and asks for a corrected full file, with the task instruction
+// chosen by repairTask. Unlike generateTests, this is a single shot per
+// round; the outer repair loop in processUnit decides how many rounds to
+// spend.
+func generateRepair(client modelClient, mc config.ModelConfig, timeout time.Duration, gen generator.Generator, unit generator.Unit, previousOutput string, outcome roundOutcome, ev *eventLogger) (string, error) {
+	ev.Log(Event{Action: "generate", File: unit.Path, Model: mc.Name})
+	prompt := strings.Join([]string{
+		"You are an expert software engineer repairing a generated test file that did not pass.",
+		repairTask(outcome),
+		"Return the complete, corrected test file. Do not use Markdown code fences, comments outside test code, or extra text.",
+		"",
+		"**Previous output:**",
+		previousOutput,
+		"",
+		"**Diagnostics:**",
+		outcome.Err.Error(),
+		"",
+		"**Code under test:**",
+		unit.Content,
+	}, "\n")
 
-	// Create temp directory for test execution
-	tempDir, err := os.MkdirTemp("", "unit-test-generator-")
+	output, err := callModel(client, mc, prompt, timeout)
 	if err != nil {
-		return false, 0.0, fmt.Errorf("failed to create temp directory: %v", err)
+		return "", fmt.Errorf("repair request failed: %v", err)
+	}
+	if err := gen.Validate(unit, output); err != nil {
+		ev.Log(Event{Action: "validate", File: unit.Path, Model: mc.Name, Error: err.Error()})
+		return "", fmt.Errorf("repaired output invalid: %v", err)
 	}
-	defer os.RemoveAll(tempDir)
+	ev.Log(Event{Action: "validate", File: unit.Path, Model: mc.Name})
+	return output, nil
+}
+
+// roundOutcome is what one build+run+coverage round produced for a unit.
+// FailedTests names the individual cases that failed, when Run reported a
+// *generator.TestFailureError; it's empty for a build failure or any other
+// error a backend can't attribute to specific test names.
+type roundOutcome struct {
+	BuildOK     bool
+	RunOK       bool
+	Coverage    float64
+	FailedTests []string
+	Err         error
+}
 
-	// Write test file to temp directory
-	tempTestFile := filepath.Join(tempDir, "test.cpp")
-	if err := os.WriteFile(tempTestFile, []byte(testFile), 0644); err != nil {
-		return false, 0.0, fmt.Errorf("failed to write test file: %v", err)
+// runRound builds and runs testSrc against unit in a fresh temp directory,
+// enforcing coverageThreshold, and reports the outcome.
+func runRound(gen generator.Generator, unit generator.Unit, testSrc string, coverageThreshold float64, ev *eventLogger) roundOutcome {
+	var out roundOutcome
+
+	tmpDir, err := os.MkdirTemp("", "unit-test-generator-")
+	if err != nil {
+		out.Err = fmt.Errorf("failed to create temp directory: %v", err)
+		return out
 	}
+	defer os.RemoveAll(tmpDir)
 
-	// Copy source file to temp directory
-	tempSourceFile := filepath.Join(tempDir, baseName)
-	if err := copyFile(sourceFile, tempSourceFile); err != nil {
-		return false, 0.0, fmt.Errorf("failed to copy source file: %v", err)
+	if err := gen.Build(tmpDir, unit, testSrc); err != nil {
+		out.Err = fmt.Errorf("build: %v", err)
+		ev.Log(Event{Action: "compile", File: unit.Path, Error: out.Err.Error()})
+		return out
 	}
+	out.BuildOK = true
+	ev.Log(Event{Action: "compile", File: unit.Path})
 
-	// Copy header file if it exists
-	if strings.HasSuffix(sourceFile, ".cpp") {
-		headerFile := strings.Replace(sourceFile, ".cpp", ".h", 1)
-		if _, err := os.Stat(headerFile); err == nil {
-			if err := copyFile(headerFile, filepath.Join(tempDir, filepath.Base(headerFile))); err != nil {
-				return false, 0.0, fmt.Errorf("failed to copy header file: %v", err)
-			}
+	passed, err := gen.Run(tmpDir)
+	if err != nil {
+		var tfe *generator.TestFailureError
+		if errors.As(err, &tfe) {
+			out.FailedTests = tfe.Failed
 		}
+		out.Err = fmt.Errorf("run: %v", err)
+		ev.Log(Event{Action: "run", File: unit.Path, Error: out.Err.Error()})
+		return out
+	}
+	out.RunOK = passed
+	if !passed {
+		out.Err = fmt.Errorf("tests did not pass")
+		ev.Log(Event{Action: "run", File: unit.Path, Error: out.Err.Error()})
+		return out
 	}
+	ev.Log(Event{Action: "run", File: unit.Path})
 
-	// Compile tests
-	compileCmd := exec.Command("g++",
-		"-std=c++17",
-		"-I"+brewPrefix+"/include",
-		"-I/usr/local/include",
-		"-I"+tempDir, // Include temp directory for headers
-		"-L"+brewPrefix+"/lib",
-		"-L/usr/local/lib",
-		"-lgtest", "-lgtest_main", "-pthread",
-		"-fprofile-arcs", "-ftest-coverage",
-		filepath.Base(tempTestFile), filepath.Base(tempSourceFile),
-		"-o", filepath.Join(tempDir, "run_tests"))
-	compileCmd.Dir = tempDir
-	compileCmd.Env = append(os.Environ(),
-		"GCOV_PREFIX="+tempDir,
-		"GCOV_PREFIX_STRIP=0")
-
-	log.Printf("Compiling tests: %s", compileCmd.String())
-	compileOutput, err := compileCmd.CombinedOutput()
+	coverage, err := gen.Coverage(tmpDir, unit)
 	if err != nil {
-		return false, 0.0, fmt.Errorf("compilation failed: %v\nOutput: %s", err, string(compileOutput))
+		out.Err = fmt.Errorf("coverage: %v", err)
+		ev.Log(Event{Action: "cover", File: unit.Path, Error: out.Err.Error()})
+		return out
 	}
-	log.Println("Tests compiled successfully")
+	out.Coverage = coverage
+	if coverage < coverageThreshold {
+		out.Err = fmt.Errorf("coverage %.2f%% is below %.2f%% threshold", coverage, coverageThreshold)
+	}
+	coverEvent := Event{Action: "cover", File: unit.Path, Coverage: coverage}
+	if out.Err != nil {
+		coverEvent.Error = out.Err.Error()
+	}
+	ev.Log(coverEvent)
+	return out
+}
 
-	// Run tests
-	runCmd := exec.Command(filepath.Join(tempDir, "run_tests"))
-	runCmd.Dir = tempDir
-	log.Printf("Running tests: %s", runCmd.String())
-	runOutput, err := runCmd.CombinedOutput()
+// Result is the outcome of processing a single unit, in both the tabulated
+// -summary view and results.json.
+type Result struct {
+	File         string  `json:"file"`
+	Model        string  `json:"model"`
+	Attempts     int     `json:"attempts"`
+	RepairRounds int     `json:"repair_rounds"`
+	CompileOK    bool    `json:"compile_ok"`
+	TestsPassed  bool    `json:"tests_passed"`
+	Coverage     float64 `json:"coverage"`
+	ElapsedMS    int64   `json:"elapsed_ms"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// debugDir returns, creating if needed, the per-unit debug directory
+// ./debug/<file>/<sub>, namespacing filePath the same way across every kind
+// of debug dump so concurrent workers processing different units never
+// collide on a shared filename.
+func debugDir(filePath, sub string) (string, error) {
+	safeName := strings.NewReplacer("/", "_", "\\", "_").Replace(filePath)
+	dir := filepath.Join("debug", safeName, sub)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// saveRawResponse persists one generation attempt's raw model output under
+// ./debug/<file>/generate/, namespaced by model and attempt number so
+// concurrent workers (one per unit) never clobber each other's dumps the
+// way a shared ./raw_response_<model>_attempt_<n>.txt would.
+func saveRawResponse(filePath, model string, attempt int, output string) error {
+	dir, err := debugDir(filePath, "generate")
 	if err != nil {
-		return false, 0.0, fmt.Errorf("tests failed: %v\nOutput: %s", err, string(runOutput))
+		return fmt.Errorf("failed to create debug dir: %v", err)
 	}
-	log.Println("Tests passed successfully")
+	name := fmt.Sprintf("model_%s_attempt_%d.txt", strings.NewReplacer("/", "_", ":", "_").Replace(model), attempt)
+	return os.WriteFile(filepath.Join(dir, name), []byte(output), 0644)
+}
 
-	// Run gcov for coverage
-	gcovCmd := exec.Command("gcov", "-r", baseName)
-	gcovCmd.Dir = tempDir
-	log.Printf("Running gcov: %s", gcovCmd.String())
-	gcovOutput, err := gcovCmd.CombinedOutput()
+// saveRepairRound persists a repair round's generated output and diagnostics
+// under ./debug/<file>/round_<k>/ for later inspection, mirroring how
+// -update_errors in Go's test/run.go keeps compiler output alongside each
+// iteration of the file it drove.
+func saveRepairRound(filePath string, round int, output string, outcome roundOutcome) {
+	dir, err := debugDir(filePath, fmt.Sprintf("round_%d", round))
 	if err != nil {
-		return true, 0.0, fmt.Errorf("gcov failed: %v\nOutput: %s", err, string(gcovOutput))
+		log.Printf("Failed to create debug dir for %s round %d: %v", filePath, round, err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, "response.txt"), []byte(output), 0644); err != nil {
+		log.Printf("Failed to save debug response for %s round %d: %v", filePath, round, err)
 	}
 
-	// Parse gcov output for coverage percentage
-	re := regexp.MustCompile(`Lines executed:([\d.]+)% of (\d+)`)
-	matches := re.FindStringSubmatch(string(gcovOutput))
-	if len(matches) < 2 {
-		return true, 0.0, fmt.Errorf("failed to parse gcov output: %s", string(gcovOutput))
+	var diag strings.Builder
+	fmt.Fprintf(&diag, "build_ok=%v tests_passed=%v coverage=%.2f\n", outcome.BuildOK, outcome.RunOK, outcome.Coverage)
+	if len(outcome.FailedTests) > 0 {
+		fmt.Fprintf(&diag, "failed_tests=%s\n", strings.Join(outcome.FailedTests, ", "))
 	}
-	coverage, err := strconv.ParseFloat(matches[1], 64)
+	if outcome.Err != nil {
+		fmt.Fprintf(&diag, "error: %v\n", outcome.Err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "diagnostics.txt"), []byte(diag.String()), 0644); err != nil {
+		log.Printf("Failed to save debug diagnostics for %s round %d: %v", filePath, round, err)
+	}
+}
+
+// processUnit generates, builds, and runs tests for a single unit via gen,
+// reporting the outcome as a Result. It never panics; all failures are
+// carried in Result.Error so the worker pool can keep going.
+//
+// When the initial generation fails to build or run, or falls below
+// cfg.CoverageThreshold, processUnit drives up
+// to maxRepairRounds rounds of diagnostics-driven repair (generateRepair),
+// keeping the best-by-coverage round seen so a flaky later repair can't
+// erase earlier progress.
+func processUnit(client modelClient, cfg *config.Config, gen generator.Generator, unit generator.Unit, maxRepairRounds int, ev *eventLogger) Result {
+	start := time.Now()
+	result := Result{File: unit.Path}
+
+	testSrc, mc, attempts, err := generateTests(client, cfg, gen, unit, ev)
+	result.Model = mc.Name
+	result.Attempts = attempts
 	if err != nil {
-		return true, 0.0, fmt.Errorf("failed to parse coverage percentage: %v", err)
+		result.Error = fmt.Sprintf("generate: %v", err)
+		result.ElapsedMS = time.Since(start).Milliseconds()
+		ev.Log(Event{Action: "done", File: unit.Path, Model: result.Model, Attempt: result.Attempts, Error: result.Error})
+		return result
 	}
-	log.Printf("Code coverage: %.2f%%", coverage)
 
-	// Enforce minimum coverage threshold (80%)
-	if coverage < 80.0 {
-		return false, coverage, fmt.Errorf("coverage %.2f%% is below 80%% threshold", coverage)
+	bestTestSrc := testSrc
+	var bestOutcome roundOutcome
+	haveBest := false
+
+	round := 0
+	for ; ; round++ {
+		outcome := runRound(gen, unit, testSrc, cfg.CoverageThreshold, ev)
+		saveRepairRound(unit.Path, round, testSrc, outcome)
+
+		if !haveBest || outcome.Coverage > bestOutcome.Coverage {
+			bestTestSrc, bestOutcome, haveBest = testSrc, outcome, true
+		}
+
+		ok := outcome.BuildOK && outcome.RunOK && outcome.Err == nil
+		if ok || round >= maxRepairRounds {
+			break
+		}
+
+		repaired, repairErr := generateRepair(client, mc, cfg.Timeout, gen, unit, testSrc, outcome, ev)
+		if repairErr != nil {
+			log.Printf("Repair round %d failed for %s: %v", round+1, unit.Path, repairErr)
+			break
+		}
+		testSrc = repaired
+	}
+	result.RepairRounds = round
+
+	result.CompileOK = bestOutcome.BuildOK
+	result.TestsPassed = bestOutcome.BuildOK && bestOutcome.RunOK && bestOutcome.Err == nil
+	result.Coverage = bestOutcome.Coverage
+	if bestOutcome.Err != nil {
+		result.Error = fmt.Sprintf("validate: %v", bestOutcome.Err)
+	}
+	if !result.TestsPassed {
+		result.ElapsedMS = time.Since(start).Milliseconds()
+		ev.Log(Event{Action: "done", File: unit.Path, Model: result.Model, Attempt: result.Attempts, Coverage: result.Coverage, Error: result.Error})
+		return result
+	}
+
+	baseName := filepath.Base(unit.Path)
+	ext := filepath.Ext(baseName)
+	testFile := filepath.Join("./tests", strings.TrimSuffix(baseName, ext)+"_test"+ext)
+	if err := os.WriteFile(testFile, []byte(bestTestSrc), 0644); err != nil {
+		result.Error = fmt.Sprintf("write: %v", err)
+	} else {
+		log.Printf("Unit tests saved to %s (coverage: %.2f%%)", testFile, bestOutcome.Coverage)
 	}
+	result.ElapsedMS = time.Since(start).Milliseconds()
+	ev.Log(Event{Action: "done", File: unit.Path, Model: result.Model, Attempt: result.Attempts, Coverage: result.Coverage, Error: result.Error})
+	return result
+}
 
-	return true, coverage, nil
+// printSummary renders results as a tab-aligned table to stderr, sorted by
+// file path so output is stable across worker-pool scheduling. It writes to
+// stderr rather than stdout so that -summary can be combined with -json
+// without the table getting appended to, and corrupting, the NDJSON event
+// stream a downstream parser reads from stdout.
+func printSummary(results []Result) {
+	sort.Slice(results, func(i, j int) bool { return results[i].File < results[j].File })
+	w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tMODEL\tATTEMPTS\tREPAIRS\tCOMPILE\tTESTS\tCOVERAGE\tELAPSED\tERROR")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%v\t%v\t%.2f%%\t%s\t%s\n",
+			r.File, r.Model, r.Attempts, r.RepairRounds, r.CompileOK, r.TestsPassed, r.Coverage,
+			time.Duration(r.ElapsedMS*int64(time.Millisecond)), r.Error)
+	}
+	w.Flush()
 }
 
-func copyFile(src, dst string) error {
-	input, err := os.ReadFile(src)
+func writeJSONFile(path string, v interface{}) error {
+	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(dst, input, 0644)
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Report is the aggregate summary written to report.json: per-file totals
+// plus a global coverage average, distinct from results.json's full
+// per-file Result dump.
+type Report struct {
+	TotalFiles      int      `json:"total_files"`
+	PassedFiles     int      `json:"passed_files"`
+	FailedFiles     int      `json:"failed_files"`
+	AverageCoverage float64  `json:"average_coverage"`
+	Files           []Result `json:"files"`
+}
+
+// buildReport totals up results into a Report. AverageCoverage is averaged
+// only over files whose tests passed, so a handful of hard failures don't
+// drag the number toward zero and obscure how well the passing files did.
+func buildReport(results []Result) Report {
+	report := Report{TotalFiles: len(results), Files: results}
+	var coverageSum float64
+	for _, r := range results {
+		if r.TestsPassed {
+			report.PassedFiles++
+			coverageSum += r.Coverage
+		} else {
+			report.FailedFiles++
+		}
+	}
+	if report.PassedFiles > 0 {
+		report.AverageCoverage = coverageSum / float64(report.PassedFiles)
+	}
+	return report
+}
+
+// fatalf reports a fatal startup error directly on stderr and exits. It's
+// used instead of log.Fatalf so fatal errors stay visible even when -v is
+// off and the standard logger has been redirected to io.Discard.
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
 }
 
 func main() {
+	workers := flag.Int("n", runtime.NumCPU(), "number of parallel workers")
+	shard := flag.Int("shard", 0, "this worker's shard index (0-based)")
+	shards := flag.Int("shards", 1, "total number of shards; files are split via fnv32(path) % shards")
+	summary := flag.Bool("summary", false, "print a tabulated per-file summary when done")
+	repairRounds := flag.Int("repair-rounds", 3, "max diagnostics-driven repair rounds per unit")
+	lang := flag.String("lang", "", fmt.Sprintf("generator backend to use (%s); auto-detected from ./codebase if omitted", strings.Join(generator.Names(), ", ")))
+	jsonOut := flag.Bool("json", false, "emit one JSON event per line to stdout (generate|validate|compile|run|cover|done), like `go test -json`")
+	verbose := flag.Bool("v", false, "print human-readable progress logs to stderr")
+	configPath := flag.String("config", "config.yaml", "path to the YAML config for the model ladder, timeouts, coverage threshold, and toolchain paths")
+	flag.Parse()
+
+	if *shards < 1 {
+		fatalf("-shards must be >= 1, got %d", *shards)
+	}
+	if *shard < 0 || *shard >= *shards {
+		fatalf("-shard must be in [0, %d), got %d", *shards, *shard)
+	}
+	if *workers < 1 {
+		fatalf("-n must be >= 1, got %d", *workers)
+	}
+
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	ev := newEventLogger(os.Stdout, *jsonOut)
 	log.Println("Starting unit test generator")
 
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fatalf("Failed to load %s: %v", *configPath, err)
+	}
+	log.Printf("Loaded config: %d model(s), coverage threshold %.1f%%, timeout %s", len(cfg.Models), cfg.CoverageThreshold, cfg.Timeout)
+
 	// Initialize Ollama client
 	ollamaURL := os.Getenv("OLLAMA_HOST")
 	if ollamaURL == "" {
@@ -312,90 +559,105 @@ func main() {
 	} else {
 		log.Println("Using OLLAMA_HOST:", ollamaURL)
 	}
-	url, err := url.Parse(ollamaURL)
+	parsedURL, err := url.Parse(ollamaURL)
 	if err != nil {
-		log.Fatalf("Invalid Ollama URL %s: %v", ollamaURL, err)
+		fatalf("Invalid Ollama URL %s: %v", ollamaURL, err)
 	}
-	client := api.NewClient(url, http.DefaultClient)
+	client := ollamaapi.NewClient(parsedURL, http.DefaultClient)
 	log.Println("Ollama client initialized")
 
 	// Check Ollama server status
 	resp, err := client.List(context.Background())
 	if err != nil {
-		log.Fatalf("Failed to connect to Ollama server: %v", err)
+		fatalf("Failed to connect to Ollama server: %v", err)
 	}
 	log.Printf("Ollama server running, available models: %v", resp.Models)
 
-	// Read codebase
 	codebaseDir := "./codebase"
-	files, err := readCodebase(codebaseDir)
-	if err != nil {
-		log.Fatalf("Failed to read codebase: %v", err)
+	langName := *lang
+	if langName == "" {
+		langName, err = generator.AutoDetect(codebaseDir)
+		if err != nil {
+			fatalf("Failed to auto-detect a language backend: %v", err)
+		}
+		log.Printf("Auto-detected -lang=%s", langName)
+	}
+	gen, ok := generator.Get(langName)
+	if !ok {
+		fatalf("Unknown -lang %q; available: %s", langName, strings.Join(generator.Names(), ", "))
+	}
+	if cg, ok := gen.(generator.Configurable); ok {
+		if err := cg.Configure(cfg); err != nil {
+			fatalf("Failed to configure %s backend: %v", langName, err)
+		}
 	}
 
 	// Create tests directory if it doesn't exist
 	if err := os.MkdirAll("./tests", 0755); err != nil {
-		log.Fatalf("Failed to create tests directory: %v", err)
+		fatalf("Failed to create tests directory: %v", err)
 	}
 	log.Println("Tests directory ready: ./tests")
 
-	// Generate, validate, and save unit tests for each file
-	for filePath, content := range files {
-		fmt.Printf("Generating unit tests for %s\n", filePath)
-		log.Printf("Processing file: %s", filePath)
-
-		// Skip files that don't have both .h and .cpp
-		if strings.HasSuffix(filePath, ".h") {
-			cppFile := strings.Replace(filePath, ".h", ".cpp", 1)
-			if _, err := os.Stat(cppFile); os.IsNotExist(err) {
-				log.Printf("Skipping %s: corresponding .cpp file not found", filePath)
-				continue
-			}
-		} else if strings.HasSuffix(filePath, ".cpp") {
-			hFile := strings.Replace(filePath, ".cpp", ".h", 1)
-			if _, err := os.Stat(hFile); os.IsNotExist(err) {
-				log.Printf("Skipping %s: corresponding .h file not found", filePath)
-				continue
-			}
-		}
-
-		tests, err := generateUnitTests(client, "qwen2.5-coder:7b", content)
-		if err != nil {
-			log.Printf("Failed to generate tests for %s: %v", filePath, err)
-			continue
-		}
-
-		// Determine source file for testing
-		sourceFile := filePath
-		if strings.HasSuffix(filePath, ".h") {
-			sourceFile = strings.Replace(filePath, ".h", ".cpp", 1)
-		}
+	units, err := gen.Discover(codebaseDir)
+	if err != nil {
+		fatalf("Failed to discover units in %s: %v", codebaseDir, err)
+	}
+	units = filterUnits(units, cfg.Include, cfg.Exclude)
 
-		// Run tests and check coverage
-		passed, coverage, err := runTestsAndCoverage(tests, sourceFile)
-		if err != nil {
-			log.Printf("Test validation failed for %s: %v", filePath, err)
-			continue
-		}
-		if !passed {
-			log.Printf("Tests did not pass for %s, skipping file write", filePath)
+	// Build this shard's worklist.
+	var shardUnits []generator.Unit
+	for _, u := range units {
+		if *shards > 1 && shardOf(u.Path, *shards) != *shard {
 			continue
 		}
-
-		// Save unit tests to a file if tests pass and coverage is sufficient
-		baseName := filepath.Base(filePath)
-		testFile := filepath.Join("./tests", strings.Replace(baseName, ".cpp", "_test.cpp", 1))
-		if strings.HasSuffix(baseName, ".h") {
-			testFile = filepath.Join("./tests", strings.Replace(baseName, ".h", "_test.cpp", 1))
+		shardUnits = append(shardUnits, u)
+	}
+	log.Printf("Shard %d/%d: %d of %d units, %d workers", *shard, *shards, len(shardUnits), len(units), *workers)
+
+	// Fan the shard's units out across a worker pool and stream results back.
+	unitCh := make(chan generator.Unit)
+	resultCh := make(chan Result)
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range unitCh {
+				log.Printf("Generating unit tests for %s", u.Path)
+				resultCh <- processUnit(client, cfg, gen, u, *repairRounds, ev)
+			}
+		}()
+	}
+	go func() {
+		for _, u := range shardUnits {
+			unitCh <- u
 		}
-		log.Printf("Writing unit tests to %s (coverage: %.2f%%)", testFile, coverage)
-		err = os.WriteFile(testFile, []byte(tests), 0644)
-		if err != nil {
-			log.Printf("Failed to write tests to %s: %v", testFile, err)
+		close(unitCh)
+	}()
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	var results []Result
+	for r := range resultCh {
+		if r.Error != "" {
+			log.Printf("%s: %s", r.File, r.Error)
 		} else {
-			fmt.Printf("Unit tests saved to %s (coverage: %.2f%%)\n", testFile, coverage)
-			log.Printf("Successfully saved unit tests to %s", testFile)
+			log.Printf("%s: tests passed, coverage %.2f%%", r.File, r.Coverage)
 		}
+		results = append(results, r)
 	}
+
+	if *summary {
+		printSummary(results)
+	}
+	if err := writeJSONFile("results.json", results); err != nil {
+		log.Printf("Failed to write results.json: %v", err)
+	}
+	if err := writeJSONFile("report.json", buildReport(results)); err != nil {
+		log.Printf("Failed to write report.json: %v", err)
+	}
+
 	log.Println("Unit test generation completed")
 }
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Achanandhi-M/unit-test-generator/config"
+	"github.com/Achanandhi-M/unit-test-generator/generator"
+	"github.com/Achanandhi-M/unit-test-generator/internal/ollamaapi"
+)
+
+// fakeClient is a scripted modelClient: each call to Generate consumes the
+// next entry in responses, in order, regardless of which model it was asked
+// for. prompts and models record what each call was asked, so a test can
+// assert on the exact sequence (model-fallback order, retry count, or the
+// wording a repair prompt used).
+type fakeClient struct {
+	responses []fakeResponse
+	prompts   []string
+	models    []string
+}
+
+type fakeResponse struct {
+	output string
+	err    error
+}
+
+func (f *fakeClient) Generate(ctx context.Context, req *ollamaapi.GenerateRequest, fn func(ollamaapi.GenerateResponse) error) error {
+	f.prompts = append(f.prompts, req.Prompt)
+	f.models = append(f.models, req.Model)
+	if len(f.responses) == 0 {
+		return fmt.Errorf("fakeClient: no more scripted responses")
+	}
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+	if resp.err != nil {
+		return resp.err
+	}
+	return fn(ollamaapi.GenerateResponse{Response: resp.output, Done: true})
+}
+
+// fakeRound scripts one runRound call's Build/Run/Coverage outcome.
+type fakeRound struct {
+	buildErr error
+	passed   bool
+	runErr   error
+	coverage float64
+}
+
+// fakeGenerator is a scripted generator.Generator: rounds[i] is consumed by
+// the i-th call to Build (which also drives the Run/Coverage results for
+// that same round), so a test can script exactly the build/run/coverage
+// sequence a repair loop should walk through.
+type fakeGenerator struct {
+	validate func(output string) error
+	rounds   []fakeRound
+	idx      int
+	cur      fakeRound
+}
+
+func (g *fakeGenerator) Discover(string) ([]generator.Unit, error) { return nil, nil }
+func (g *fakeGenerator) Prompt(generator.Unit) (string, error)     { return "prompt", nil }
+
+func (g *fakeGenerator) Validate(_ generator.Unit, output string) error {
+	if g.validate == nil {
+		return nil
+	}
+	return g.validate(output)
+}
+
+func (g *fakeGenerator) Build(string, generator.Unit, string) error {
+	g.cur = g.rounds[g.idx]
+	g.idx++
+	return g.cur.buildErr
+}
+
+func (g *fakeGenerator) Run(string) (bool, error) {
+	return g.cur.passed, g.cur.runErr
+}
+
+func (g *fakeGenerator) Coverage(string, generator.Unit) (float64, error) {
+	return g.cur.coverage, nil
+}
+
+func testConfig(retryCount int, models ...string) *config.Config {
+	cfg := &config.Config{RetryCount: retryCount, Timeout: time.Second, CoverageThreshold: 80}
+	for _, m := range models {
+		cfg.Models = append(cfg.Models, config.ModelConfig{Name: m})
+	}
+	return cfg
+}
+
+func TestGenerateTests_ModelFallbackLadder(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{
+		{output: "bad"},
+		{output: "good"},
+	}}
+	gen := &fakeGenerator{validate: func(output string) error {
+		if output != "good" {
+			return fmt.Errorf("rejected: %s", output)
+		}
+		return nil
+	}}
+	cfg := testConfig(1, "primary", "backup")
+
+	output, mc, attempts, err := generateTests(client, cfg, gen, generator.Unit{Name: "Thing"}, nil)
+	if err != nil {
+		t.Fatalf("generateTests() error = %v", err)
+	}
+	if output != "good" || mc.Name != "backup" || attempts != 1 {
+		t.Fatalf("generateTests() = (%q, %+v, %d), want (good, backup, 1)", output, mc, attempts)
+	}
+	if want := []string{"primary", "backup"}; !equalStrings(client.models, want) {
+		t.Errorf("models called = %v, want %v", client.models, want)
+	}
+}
+
+func TestGenerateTests_RetriesWithinAModel(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{
+		{err: fmt.Errorf("connection refused")},
+		{output: "good"},
+	}}
+	gen := &fakeGenerator{}
+	cfg := testConfig(2, "only")
+
+	output, mc, attempts, err := generateTests(client, cfg, gen, generator.Unit{Name: "Thing"}, nil)
+	if err != nil {
+		t.Fatalf("generateTests() error = %v", err)
+	}
+	if output != "good" || mc.Name != "only" || attempts != 2 {
+		t.Fatalf("generateTests() = (%q, %+v, %d), want (good, only, 2)", output, mc, attempts)
+	}
+}
+
+func TestGenerateTests_AllModelsExhausted(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{{output: "bad"}, {output: "bad"}}}
+	gen := &fakeGenerator{validate: func(string) error { return fmt.Errorf("never good enough") }}
+	cfg := testConfig(1, "only")
+
+	if _, _, _, err := generateTests(client, cfg, gen, generator.Unit{Name: "Thing"}, nil); err == nil {
+		t.Fatal("generateTests() error = nil, want an error once every model is exhausted")
+	}
+}
+
+func TestProcessUnit_RepairLoopFixesACoverageShortfall(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{
+		{output: "v1"}, // initial generation
+		{output: "v2"}, // repair round
+	}}
+	gen := &fakeGenerator{rounds: []fakeRound{
+		{passed: true, coverage: 50}, // built and ran fine, but under threshold
+		{passed: true, coverage: 90}, // repaired version clears it
+	}}
+	cfg := testConfig(1, "only")
+
+	result := processUnit(client, cfg, gen, generator.Unit{Name: "Thing", Path: "thing.cpp"}, 2, nil)
+
+	if !result.TestsPassed || result.Coverage != 90 || result.RepairRounds != 1 {
+		t.Fatalf("processUnit() = %+v, want TestsPassed=true Coverage=90 RepairRounds=1", result)
+	}
+	if len(client.prompts) != 2 {
+		t.Fatalf("expected 2 model calls (generate + repair), got %d", len(client.prompts))
+	}
+	if want := "coverage is below the threshold"; !strings.Contains(client.prompts[1], want) {
+		t.Errorf("repair prompt = %q, want it to mention %q", client.prompts[1], want)
+	}
+}
+
+func TestProcessUnit_GivesUpAfterMaxRepairRounds(t *testing.T) {
+	client := &fakeClient{responses: []fakeResponse{
+		{output: "v1"},
+		{output: "v2"},
+	}}
+	gen := &fakeGenerator{rounds: []fakeRound{
+		{buildErr: fmt.Errorf("syntax error")},
+		{buildErr: fmt.Errorf("still broken")},
+	}}
+	cfg := testConfig(1, "only")
+
+	result := processUnit(client, cfg, gen, generator.Unit{Name: "Thing", Path: "thing.cpp"}, 1, nil)
+
+	if result.TestsPassed {
+		t.Fatalf("processUnit() = %+v, want TestsPassed=false after exhausting repair rounds", result)
+	}
+	if want := "The file failed to build or run"; !strings.Contains(client.prompts[1], want) {
+		t.Errorf("repair prompt = %q, want it to mention %q", client.prompts[1], want)
+	}
+}
+
+func equalStrings(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}